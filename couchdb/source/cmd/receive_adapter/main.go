@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"knative.dev/eventing-contrib/couchdb/source/pkg/adapter"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("failed to build logger: %v", err)
+	}
+	defer logger.Sync()
+
+	cfg := adapter.Config{
+		CouchDbURL:    os.Getenv("COUCHDB_CREDENTIALS"),
+		EventSource:   os.Getenv("EVENT_SOURCE"),
+		Database:      os.Getenv("DATABASE"),
+		Databases:     parseDatabases(os.Getenv("DATABASES")),
+		Selector:      []byte(os.Getenv("SELECTOR")),
+		SinkURI:       os.Getenv("SINK_URI"),
+		CEOverrides:   parseCEOverrides(os.Getenv("K_CE_OVERRIDES"), logger.Sugar()),
+		OIDCAudience:  os.Getenv("OIDC_AUDIENCE"),
+		OIDCTokenPath: "/var/run/secrets/tokens/couchdb-source-token",
+		MetricsAddr:   os.Getenv("METRICS_ADDR"),
+
+		CheckpointConfigMap: os.Getenv("CHECKPOINT_CONFIGMAP"),
+		CheckpointNamespace: os.Getenv("CHECKPOINT_NAMESPACE"),
+		CheckpointMountPath: "/etc/couchdb-source/checkpoint",
+	}
+
+	a, err := adapter.NewAdapter(ctx, cfg, logger.Sugar())
+	if err != nil {
+		logger.Fatal("failed to create adapter", zap.Error(err))
+	}
+	if err := a.Start(ctx); err != nil {
+		logger.Fatal("adapter stopped", zap.Error(err))
+	}
+}
+
+// parseDatabases splits the comma-separated DATABASES env var populated from
+// spec.databases.
+func parseDatabases(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
+
+// parseCEOverrides decodes the JSON-encoded extension attribute map the
+// reconciler attaches via the K_CE_OVERRIDES env var.
+func parseCEOverrides(raw string, logger *zap.SugaredLogger) map[string]string {
+	if raw == "" {
+		return nil
+	}
+	overrides := map[string]string{}
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		logger.Errorw("failed to parse K_CE_OVERRIDES", zap.Error(err))
+		return nil
+	}
+	return overrides
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDatabases(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{{
+		name: "empty",
+		raw:  "",
+		want: nil,
+	}, {
+		name: "single",
+		raw:  "orders",
+		want: []string{"orders"},
+	}, {
+		name: "multiple",
+		raw:  "orders,users,invoices",
+		want: []string{"orders", "users", "invoices"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := parseDatabases(test.raw)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseDatabases(%q) = %v, want %v", test.raw, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// checkpointFlushInterval is how often the last-delivered sequence per
+// database is patched back to the checkpoint ConfigMap.
+const checkpointFlushInterval = 5 * time.Second
+
+// checkpoint persists, per watched database, the last _changes sequence the
+// adapter has successfully delivered, so a restart resumes with `?since=`
+// instead of replaying or dropping events. The initial sequence is read from
+// the ConfigMap as projected into the pod's filesystem; updates are patched
+// back through the Kubernetes API, since a ConfigMap volume mount is
+// read-only.
+type checkpoint struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	mountPath string
+	logger    *zap.SugaredLogger
+
+	mu      sync.Mutex
+	pending map[string]string
+}
+
+// newCheckpoint builds a checkpoint backed by the in-cluster Kubernetes API
+// for namespace/name, with mountPath pointing at where that same ConfigMap is
+// projected as a volume.
+func newCheckpoint(namespace, name, mountPath string, logger *zap.SugaredLogger) (*checkpoint, error) {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading in-cluster config: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("building kubernetes client: %w", err)
+	}
+	return &checkpoint{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+		mountPath: mountPath,
+		logger:    logger,
+		pending:   map[string]string{},
+	}, nil
+}
+
+// since returns the last checkpointed sequence for db, or "" if none has
+// been recorded yet.
+func (c *checkpoint) since(db string) string {
+	raw, err := ioutil.ReadFile(filepath.Join(c.mountPath, db))
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+// record queues seq as the last-delivered sequence for db, to be flushed to
+// the ConfigMap by run.
+func (c *checkpoint) record(db, seq string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[db] = seq
+}
+
+// run flushes queued sequences to the checkpoint ConfigMap every
+// checkpointFlushInterval until ctx is done.
+func (c *checkpoint) run(ctx context.Context) error {
+	ticker := time.NewTicker(checkpointFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			c.flush()
+			return ctx.Err()
+		case <-ticker.C:
+			c.flush()
+		}
+	}
+}
+
+func (c *checkpoint) flush() {
+	c.mu.Lock()
+	if len(c.pending) == 0 {
+		c.mu.Unlock()
+		return
+	}
+	data := c.pending
+	c.pending = map[string]string{}
+	c.mu.Unlock()
+
+	patch, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		c.logger.Errorw("failed to marshal checkpoint patch", zap.Error(err))
+		return
+	}
+	if _, err := c.client.CoreV1().ConfigMaps(c.namespace).Patch(c.name, types.MergePatchType, patch); err != nil {
+		c.logger.Errorw("failed to patch checkpoint configmap", zap.String("configmap", c.name), zap.Error(err))
+	}
+}
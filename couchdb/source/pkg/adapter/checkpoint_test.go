@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckpointSince(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatalf("TempDir() error = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &checkpoint{mountPath: dir}
+	if got := c.since("orders"); got != "" {
+		t.Errorf("since() on missing file = %q, want empty", got)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "orders"), []byte("15-g1AAAAI9eJ"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if got, want := c.since("orders"), "15-g1AAAAI9eJ"; got != want {
+		t.Errorf("since() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckpointFlush(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-source-checkpoint", Namespace: "default"},
+	}
+	client := fake.NewSimpleClientset(cm)
+	logger := zap.NewNop().Sugar()
+
+	c := &checkpoint{
+		client:    client,
+		namespace: "default",
+		name:      "my-source-checkpoint",
+		logger:    logger,
+		pending:   map[string]string{},
+	}
+
+	// Nothing pending: flush must not call Patch.
+	c.flush()
+
+	c.record("orders", "15-g1AAAAI9eJ")
+	c.flush()
+
+	got, err := client.CoreV1().ConfigMaps("default").Get("my-source-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if want := "15-g1AAAAI9eJ"; got.Data["orders"] != want {
+		t.Errorf("configmap data[orders] = %q, want %q", got.Data["orders"], want)
+	}
+	if len(c.pending) != 0 {
+		t.Errorf("pending not cleared after flush: %v", c.pending)
+	}
+}
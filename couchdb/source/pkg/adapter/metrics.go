@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"sync"
+	"time"
+)
+
+// lagMetric tracks, per watched database, the number of _changes entries
+// CouchDB still has pending past the sequence the adapter has last
+// processed. A custom collector scrapes this to feed the PodAutoscaler's
+// couchdb.knative.dev/lag metric.
+//
+// CouchDB 2.x/3.x emit update_seq as an opaque, backend-specific token (e.g.
+// "15-g1AAAAI9eJ...") rather than a bare integer, so it cannot be subtracted
+// from the last processed sequence to get a magnitude. Instead, lag asks
+// CouchDB itself via `_changes?since=<lastSeq>&limit=0`, whose `pending`
+// field is a real integer count of changes after since, so it's comparable
+// to an arbitrary ScaleSpec.TargetLag.
+type lagMetric struct {
+	mu        sync.Mutex
+	lastSeq   map[string]string
+	pendingFn func(ctx context.Context, db, since string) (int64, error)
+}
+
+func newLagMetric(couchDbURL string) *lagMetric {
+	return &lagMetric{
+		lastSeq: map[string]string{},
+		pendingFn: func(ctx context.Context, db, since string) (int64, error) {
+			url := couchDbURL + "/" + db + "/_changes?limit=0"
+			if since != "" {
+				url += "&since=" + neturl.QueryEscape(since)
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return 0, err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return 0, err
+			}
+			defer resp.Body.Close()
+
+			var feed struct {
+				Pending int64 `json:"pending"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+				return 0, err
+			}
+			return feed.Pending, nil
+		},
+	}
+}
+
+// recordProcessed records the last _changes sequence the adapter has
+// successfully delivered for db.
+func (m *lagMetric) recordProcessed(db, seq string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastSeq[db] = seq
+}
+
+// lag returns, for every watched database, the number of _changes entries
+// CouchDB has pending past the last processed sequence.
+func (m *lagMetric) lag(ctx context.Context, databases []string) (map[string]int64, error) {
+	lags := make(map[string]int64, len(databases))
+	for _, db := range databases {
+		m.mu.Lock()
+		since := m.lastSeq[db]
+		m.mu.Unlock()
+		pending, err := m.pendingFn(ctx, db, since)
+		if err != nil {
+			return nil, fmt.Errorf("fetching pending _changes count for database %q: %w", db, err)
+		}
+		lags[db] = pending
+	}
+	return lags, nil
+}
+
+// ServeMetrics serves couchdb.knative.dev/lag as a Prometheus gauge per
+// watched database, in the text exposition format.
+func (m *lagMetric) ServeMetrics(databases []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		lags, err := m.lag(ctx, databases)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprintln(w, "# HELP couchdb_changes_lag Pending _changes entries per database, past the adapter's last processed sequence.")
+		fmt.Fprintln(w, "# TYPE couchdb_changes_lag gauge")
+		for db, lag := range lags {
+			fmt.Fprintf(w, "couchdb_changes_lag{database=%q} %d\n", db, lag)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLagMetricLag(t *testing.T) {
+	pending := map[string]int64{
+		"caught-up": 0,
+		"behind":    42,
+	}
+	var m *lagMetric
+	m = &lagMetric{
+		lastSeq: map[string]string{
+			"caught-up": "15-g1AAAAI9eJ",
+			"behind":    "10-g1AAAAI9eA",
+		},
+		pendingFn: func(ctx context.Context, db, since string) (int64, error) {
+			if want := m.lastSeq[db]; since != want {
+				t.Errorf("pendingFn(%q) since = %q, want %q", db, since, want)
+			}
+			return pending[db], nil
+		},
+	}
+
+	lags, err := m.lag(context.Background(), []string{"caught-up", "behind"})
+	if err != nil {
+		t.Fatalf("lag() error = %v", err)
+	}
+	if lags["caught-up"] != 0 {
+		t.Errorf("lag[caught-up] = %d, want 0", lags["caught-up"])
+	}
+	if lags["behind"] != 42 {
+		t.Errorf("lag[behind] = %d, want 42", lags["behind"])
+	}
+}
+
+func TestLagMetricRecordProcessed(t *testing.T) {
+	m := &lagMetric{
+		lastSeq: map[string]string{},
+		pendingFn: func(ctx context.Context, db, since string) (int64, error) {
+			if since != "5-abc" {
+				t.Errorf("pendingFn(%q) since = %q, want %q", db, since, "5-abc")
+			}
+			return 0, nil
+		},
+	}
+	m.recordProcessed("orders", "5-abc")
+
+	lags, err := m.lag(context.Background(), []string{"orders"})
+	if err != nil {
+		t.Fatalf("lag() error = %v", err)
+	}
+	if lags["orders"] != 0 {
+		t.Errorf("lag[orders] = %d, want 0", lags["orders"])
+	}
+}
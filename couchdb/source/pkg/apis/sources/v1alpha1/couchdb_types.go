@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+const (
+	// CouchDbSourceUpdateEventType is the CouchDbSource CloudEvent type for document
+	// creations and updates.
+	CouchDbSourceUpdateEventType = "com.couchdb.document.update"
+	// CouchDbSourceDeleteEventType is the CouchDbSource CloudEvent type for document
+	// deletions.
+	CouchDbSourceDeleteEventType = "com.couchdb.document.delete"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CouchDbSource is the Schema for the couchdbsources API.
+type CouchDbSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CouchDbSourceSpec   `json:"spec,omitempty"`
+	Status CouchDbSourceStatus `json:"status,omitempty"`
+}
+
+// CouchDbSourceSpec defines the desired state of CouchDbSource.
+type CouchDbSourceSpec struct {
+	// CouchDbCredentials is the reference to a Secret containing the "url" field
+	// used to reach the CouchDB instance.
+	CouchDbCredentials corev1.SecretKeySelector `json:"couchDbCredentials"`
+
+	// Database is the name of the CouchDB database whose _changes feed is watched.
+	//
+	// Deprecated: use Databases instead. Database and Databases are mutually
+	// exclusive.
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// Databases is the list of CouchDB databases whose _changes feeds are
+	// watched. Database and Databases are mutually exclusive.
+	// +optional
+	Databases []string `json:"databases,omitempty"`
+
+	// Selector is a CouchDB Mango selector (the "selector" field of a
+	// `_changes?filter=_selector` request body) used to filter the documents
+	// forwarded from every database in Databases. Selector requires
+	// Databases to be set.
+	// +optional
+	Selector *apiextensionsv1.JSON `json:"selector,omitempty"`
+
+	// Sink is where events are delivered to.
+	Sink *duckv1beta1.Destination `json:"sink,omitempty"`
+
+	// CloudEventOverrides defines overrides to control the output format and
+	// modifications of the event sent to the sink.
+	// +optional
+	CloudEventOverrides *duckv1.CloudEventOverrides `json:"ceOverrides,omitempty"`
+
+	// Scale configures autoscaling of the receive adapter off _changes feed
+	// lag instead of the default single-replica Deployment.
+	// +optional
+	Scale *ScaleSpec `json:"scale,omitempty"`
+
+	// CheckpointRef points at the ConfigMap the receive adapter persists its
+	// last successfully-delivered _changes sequence to, so a restart resumes
+	// instead of replaying or dropping events. When unset, a ConfigMap named
+	// "<name>-checkpoint" is created and owned by the source.
+	// +optional
+	CheckpointRef *corev1.LocalObjectReference `json:"checkpointRef,omitempty"`
+}
+
+// ScaleSpec configures the Knative PodAutoscaler backing the receive
+// adapter when a CouchDbSource opts into autoscaling.
+type ScaleSpec struct {
+	// MinReplicas is the minimum number of adapter replicas to keep running.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the maximum number of adapter replicas to scale up to.
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetLag is the number of unprocessed _changes sequence entries the
+	// autoscaler targets per replica.
+	TargetLag int64 `json:"targetLag"`
+}
+
+// CouchDbSourceStatus defines the observed state of CouchDbSource.
+type CouchDbSourceStatus struct {
+	duckv1beta1.SourceStatus `json:",inline"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CouchDbSourceList contains a list of CouchDbSource.
+type CouchDbSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CouchDbSource `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object. The generated implementation
+// normally lives in zz_generated.deepcopy.go via deepcopy-gen.
+func (s *CouchDbSource) DeepCopyObject() runtime.Object {
+	out := new(CouchDbSource)
+	*out = *s
+	return out
+}
+
+// DeepCopyObject implements runtime.Object. The generated implementation
+// normally lives in zz_generated.deepcopy.go via deepcopy-gen.
+func (s *CouchDbSourceList) DeepCopyObject() runtime.Object {
+	out := new(CouchDbSourceList)
+	*out = *s
+	return out
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"knative.dev/pkg/apis"
+)
+
+// Validate implements apis.Validatable.
+func (s *CouchDbSource) Validate(ctx context.Context) *apis.FieldError {
+	return s.Spec.Validate(ctx).ViaField("spec")
+}
+
+// Validate implements apis.Validatable.
+func (s *CouchDbSourceSpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+
+	if s.Database != "" && len(s.Databases) > 0 {
+		errs = errs.Also(apis.ErrMultipleOneOf("database", "databases"))
+	} else if s.Database == "" && len(s.Databases) == 0 {
+		errs = errs.Also(apis.ErrMissingOneOf("database", "databases"))
+	}
+
+	if s.Selector != nil && len(s.Databases) == 0 {
+		errs = errs.Also(&apis.FieldError{
+			Message: "selector requires databases to be set",
+			Paths:   []string{"selector"},
+		})
+	}
+
+	if s.Scale != nil {
+		errs = errs.Also(s.Scale.Validate(ctx).ViaField("scale"))
+	}
+
+	if s.CheckpointRef != nil && s.CheckpointRef.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("checkpointRef.name"))
+	}
+
+	return errs
+}
+
+// Validate implements apis.Validatable.
+func (s *ScaleSpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if s.MaxReplicas <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(s.MaxReplicas, "maxReplicas"))
+	}
+	if s.MinReplicas != nil && *s.MinReplicas > s.MaxReplicas {
+		errs = errs.Also(apis.ErrInvalidValue(*s.MinReplicas, "minReplicas"))
+	}
+	if s.TargetLag <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(s.TargetLag, "targetLag"))
+	}
+	return errs
+}
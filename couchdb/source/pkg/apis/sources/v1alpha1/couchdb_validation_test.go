@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestCouchDbSourceSpecValidate(t *testing.T) {
+	selector := &apiextensionsv1.JSON{Raw: []byte(`{"_id":{"$gt":null}}`)}
+
+	tests := []struct {
+		name    string
+		spec    CouchDbSourceSpec
+		wantErr bool
+	}{{
+		name:    "valid with database",
+		spec:    CouchDbSourceSpec{Database: "orders"},
+		wantErr: false,
+	}, {
+		name:    "valid with databases",
+		spec:    CouchDbSourceSpec{Databases: []string{"orders", "users"}},
+		wantErr: false,
+	}, {
+		name:    "database and databases both set",
+		spec:    CouchDbSourceSpec{Database: "orders", Databases: []string{"users"}},
+		wantErr: true,
+	}, {
+		name:    "neither database nor databases set",
+		spec:    CouchDbSourceSpec{},
+		wantErr: true,
+	}, {
+		name:    "selector without databases",
+		spec:    CouchDbSourceSpec{Database: "orders", Selector: selector},
+		wantErr: true,
+	}, {
+		name:    "selector with databases",
+		spec:    CouchDbSourceSpec{Databases: []string{"orders"}, Selector: selector},
+		wantErr: false,
+	}, {
+		name:    "checkpointRef missing name",
+		spec:    CouchDbSourceSpec{Database: "orders", CheckpointRef: &corev1.LocalObjectReference{}},
+		wantErr: true,
+	}, {
+		name:    "checkpointRef with name",
+		spec:    CouchDbSourceSpec{Database: "orders", CheckpointRef: &corev1.LocalObjectReference{Name: "my-checkpoint"}},
+		wantErr: false,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.spec.Validate(context.Background())
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestScaleSpecValidate(t *testing.T) {
+	min2 := int32(2)
+	min5 := int32(5)
+
+	tests := []struct {
+		name    string
+		spec    ScaleSpec
+		wantErr bool
+	}{{
+		name:    "valid",
+		spec:    ScaleSpec{MinReplicas: &min2, MaxReplicas: 5, TargetLag: 100},
+		wantErr: false,
+	}, {
+		name:    "valid without min",
+		spec:    ScaleSpec{MaxReplicas: 5, TargetLag: 100},
+		wantErr: false,
+	}, {
+		name:    "maxReplicas not positive",
+		spec:    ScaleSpec{MaxReplicas: 0, TargetLag: 100},
+		wantErr: true,
+	}, {
+		name:    "minReplicas greater than maxReplicas",
+		spec:    ScaleSpec{MinReplicas: &min5, MaxReplicas: 2, TargetLag: 100},
+		wantErr: true,
+	}, {
+		name:    "targetLag not positive",
+		spec:    ScaleSpec{MaxReplicas: 5, TargetLag: 0},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.spec.Validate(context.Background())
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+
+	"knative.dev/pkg/apis"
+)
+
+var condSet = apis.NewLivingConditionSet(
+	ConditionSinkProvided,
+	ConditionDeployed,
+	ConditionEventTypesProvided,
+	ConditionEventPoliciesReady,
+	ConditionCheckpointReady,
+)
+
+const (
+	// ConditionReady is set when the source is ready to begin forwarding events.
+	ConditionReady = apis.ConditionReady
+	// ConditionSinkProvided is set when the sink has resolved to a URI.
+	ConditionSinkProvided apis.ConditionType = "SinkProvided"
+	// ConditionDeployed is set when the receive adapter Deployment is available.
+	ConditionDeployed apis.ConditionType = "Deployed"
+	// ConditionEventTypesProvided is set when the EventTypes for this source have
+	// been reconciled.
+	ConditionEventTypesProvided apis.ConditionType = "EventTypesProvided"
+	// ConditionEventPoliciesReady is set when every eventing.knative.dev/v1alpha1
+	// EventPolicy selecting this CouchDbSource has converged. It defaults to
+	// True when no EventPolicy selects the source.
+	ConditionEventPoliciesReady apis.ConditionType = "EventPoliciesReady"
+	// ConditionCheckpointReady is set when the ConfigMap the receive adapter
+	// persists its _changes checkpoint to exists and, unless it is named by
+	// spec.checkpointRef, is owned by the source.
+	ConditionCheckpointReady apis.ConditionType = "CheckpointReady"
+)
+
+// InitializeConditions sets relevant unset conditions to Unknown state.
+func (s *CouchDbSourceStatus) InitializeConditions() {
+	condSet.Manage(s).InitializeConditions()
+}
+
+// MarkSink sets the SinkProvided condition to True using the given URI.
+func (s *CouchDbSourceStatus) MarkSink(uri string) {
+	s.SinkURI = uri
+	if uri != "" {
+		condSet.Manage(s).MarkTrue(ConditionSinkProvided)
+	} else {
+		condSet.Manage(s).MarkUnknown(ConditionSinkProvided, "SinkEmpty", "Sink has resolved to empty")
+	}
+}
+
+// MarkSinkWarnRefDeprecated sets the SinkProvided condition to True, with a
+// warning that deprecated fields were used to resolve the sink.
+func (s *CouchDbSourceStatus) MarkSinkWarnRefDeprecated(uri string) {
+	s.SinkURI = uri
+	condSet.Manage(s).MarkTrueWithReason(ConditionSinkProvided, "SinkRefDeprecated",
+		"Using deprecated object ref fields when specifying spec.sink.")
+}
+
+// MarkNoSink sets the SinkProvided condition to False.
+func (s *CouchDbSourceStatus) MarkNoSink(reason, messageFormat string, messageA ...interface{}) {
+	condSet.Manage(s).MarkFalse(ConditionSinkProvided, reason, messageFormat, messageA...)
+}
+
+// MarkEventTypes sets the EventTypesProvided condition to True.
+func (s *CouchDbSourceStatus) MarkEventTypes() {
+	condSet.Manage(s).MarkTrue(ConditionEventTypesProvided)
+}
+
+// MarkNoEventTypes sets the EventTypesProvided condition to False.
+func (s *CouchDbSourceStatus) MarkNoEventTypes(reason, messageFormat string, messageA ...interface{}) {
+	condSet.Manage(s).MarkFalse(ConditionEventTypesProvided, reason, messageFormat, messageA...)
+}
+
+// MarkEventPoliciesTrue sets the EventPoliciesReady condition to True, either
+// because no EventPolicy selects this source, or because every selecting
+// EventPolicy has converged.
+func (s *CouchDbSourceStatus) MarkEventPoliciesTrue() {
+	condSet.Manage(s).MarkTrue(ConditionEventPoliciesReady)
+}
+
+// MarkEventPoliciesFalse sets the EventPoliciesReady condition to False,
+// e.g. because an EventPolicy selecting this source has not converged.
+func (s *CouchDbSourceStatus) MarkEventPoliciesFalse(reason, messageFormat string, messageA ...interface{}) {
+	condSet.Manage(s).MarkFalse(ConditionEventPoliciesReady, reason, messageFormat, messageA...)
+}
+
+// MarkCheckpointReady sets the CheckpointReady condition to True.
+func (s *CouchDbSourceStatus) MarkCheckpointReady() {
+	condSet.Manage(s).MarkTrue(ConditionCheckpointReady)
+}
+
+// MarkNoCheckpoint sets the CheckpointReady condition to False.
+func (s *CouchDbSourceStatus) MarkNoCheckpoint(reason, messageFormat string, messageA ...interface{}) {
+	condSet.Manage(s).MarkFalse(ConditionCheckpointReady, reason, messageFormat, messageA...)
+}
+
+// PropagateDeploymentAvailability uses the availability of the given Deployment
+// to determine if the Deployed condition should be marked true or false.
+func (s *CouchDbSourceStatus) PropagateDeploymentAvailability(d *appsv1.Deployment) {
+	if d == nil {
+		condSet.Manage(s).MarkUnknown(ConditionDeployed, "DeploymentUnavailable", "Deployment is not available")
+		return
+	}
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentAvailable {
+			if cond.Status == "True" {
+				condSet.Manage(s).MarkTrue(ConditionDeployed)
+			} else {
+				condSet.Manage(s).MarkFalse(ConditionDeployed, cond.Reason, cond.Message)
+			}
+			return
+		}
+	}
+	condSet.Manage(s).MarkUnknown(ConditionDeployed, "DeploymentUnavailable", "Deployment is not available")
+}
+
+// IsReady returns true if the resource is ready overall.
+func (s *CouchDbSourceStatus) IsReady() bool {
+	return condSet.Manage(s).IsHappy()
+}
+
+// GetCondition returns the condition currently associated with the given type,
+// or nil if there is no condition associated with that type.
+func (s *CouchDbSourceStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return condSet.Manage(s).GetCondition(t)
+}
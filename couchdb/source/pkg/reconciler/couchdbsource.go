@@ -18,8 +18,10 @@ package reconciler
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"sort"
 
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
@@ -30,12 +32,20 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	servingclientset "github.com/knative/serving/pkg/client/clientset/versioned"
+	servinglisters "github.com/knative/serving/pkg/client/listers/serving/v1alpha1"
+	cdbclientset "knative.dev/eventing-contrib/couchdb/source/pkg/client/clientset/versioned"
 	cdbreconciler "knative.dev/eventing-contrib/couchdb/source/pkg/client/injection/reconciler/sources/v1alpha1/couchdbsource"
 	"knative.dev/eventing-contrib/couchdb/source/pkg/reconciler/resources"
 	eventingv1alpha1 "knative.dev/eventing/pkg/apis/eventing/v1alpha1"
 	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1alpha1"
 	"knative.dev/eventing/pkg/logging"
 	"knative.dev/eventing/pkg/reconciler"
+	"knative.dev/pkg/kmeta"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/resolver"
 
@@ -47,9 +57,21 @@ const (
 	couchdbsourceDeploymentCreated = "CouchDbSourceDeploymentCreated"
 	couchdbsourceDeploymentUpdated = "CouchDbSourceDeploymentUpdated"
 
+	// eventPolicyNotReady is the corev1.Event reason emitted when an
+	// EventPolicy selecting this source has not yet converged.
+	eventPolicyNotReady = "EventPolicyNotReady"
+
 	// raImageEnvVar is the name of the environment variable that contains the receive adapter's
 	// image. It must be defined.
 	raImageEnvVar = "COUCHDB_RA_IMAGE"
+
+	// backstageIDAnnotation lets a Backstage catalog entry be located for this
+	// CouchDbSource.
+	backstageIDAnnotation = "backstage.io/kubernetes-id"
+	// consumedByAnnotation is stamped on every EventType this source owns with
+	// a JSON list of the Triggers that currently subscribe to it, so external
+	// catalog tools can discover producer->consumer edges without a separate CRD.
+	consumedByAnnotation = "eventing.knative.dev/consumed-by"
 )
 
 var (
@@ -65,9 +87,16 @@ type Reconciler struct {
 
 	receiveAdapterImage string
 
+	couchdbClientSet cdbclientset.Interface
+	servingClientSet servingclientset.Interface
+
 	// listers index properties about resources
-	deploymentLister appsv1listers.DeploymentLister
-	eventTypeLister  eventinglisters.EventTypeLister
+	deploymentLister    appsv1listers.DeploymentLister
+	eventTypeLister     eventinglisters.EventTypeLister
+	eventPolicyLister   eventinglisters.EventPolicyLister
+	configMapLister     corev1listers.ConfigMapLister
+	triggerLister       eventinglisters.TriggerLister
+	podAutoscalerLister servinglisters.PodAutoscalerLister
 
 	sinkResolver *resolver.URIResolver
 }
@@ -77,6 +106,11 @@ var _ cdbreconciler.Interface = (*Reconciler)(nil)
 func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1alpha1.CouchDbSource) pkgreconciler.Event {
 	source.Status.InitializeConditions()
 
+	if err := r.reconcileBackstageID(source); err != nil {
+		r.Logger.Error("Unable to stamp the backstage ID annotation", zap.Error(err))
+		return err
+	}
+
 	if source.Spec.Sink == nil {
 		source.Status.MarkNoSink("SinkMissing", "")
 		return fmt.Errorf("spec.sink missing")
@@ -111,7 +145,20 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1alpha1.CouchDb
 		source.Status.MarkSink(sinkURI)
 	}
 
-	ra, err := r.createReceiveAdapter(ctx, source, sinkURI)
+	hasEventPolicy, err := r.reconcileEventPolicies(ctx, source)
+	if err != nil {
+		r.Logger.Error("Unable to reconcile event policies", zap.Error(err))
+		return err
+	}
+
+	if err := r.reconcileCheckpoint(ctx, source); err != nil {
+		r.Logger.Error("Unable to reconcile the checkpoint configmap", zap.Error(err))
+		source.Status.MarkNoCheckpoint("CheckpointReconcileFailed", "%v", err)
+		return err
+	}
+	source.Status.MarkCheckpointReady()
+
+	ra, err := r.createReceiveAdapter(ctx, source, sinkURI, hasEventPolicy)
 	if err != nil {
 		r.Logger.Error("Unable to create the receive adapter", zap.Error(err))
 		return err
@@ -119,6 +166,11 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1alpha1.CouchDb
 	// Update source status// Update source status
 	source.Status.PropagateDeploymentAvailability(ra)
 
+	if err := r.reconcilePodAutoscaler(ctx, source); err != nil {
+		r.Logger.Error("Unable to reconcile the pod autoscaler", zap.Error(err))
+		return err
+	}
+
 	err = r.reconcileEventTypes(ctx, source)
 	if err != nil {
 		source.Status.MarkNoEventTypes("EventTypesReconcileFailed", "")
@@ -129,19 +181,57 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, source *v1alpha1.CouchDb
 	return nil
 }
 
-func (r *Reconciler) createReceiveAdapter(ctx context.Context, src *v1alpha1.CouchDbSource, sinkURI string) (*appsv1.Deployment, error) {
-	eventSource, err := r.makeEventSource(src)
+// reconcileBackstageID stamps backstageIDAnnotation on source the first time
+// it's reconciled.
+//
+// The genreconciler wrapper calling ReconcileKind only persists the returned
+// object's Status (via UpdateStatus); it never writes back Spec or
+// ObjectMeta changes a ReconcileKind implementation makes on its argument.
+// Mutating source.Annotations in place, as this used to do, was therefore
+// silently dropped every reconcile. Patch it explicitly instead, the same
+// way EventType annotations are persisted through an explicit client call in
+// reconcileEventTypes.
+func (r *Reconciler) reconcileBackstageID(source *v1alpha1.CouchDbSource) error {
+	if source.Annotations[backstageIDAnnotation] != "" {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				backstageIDAnnotation: source.Name,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("marshalling backstage annotation patch: %w", err)
+	}
+	if _, err := r.couchdbClientSet.SourcesV1alpha1().CouchDbSources(source.Namespace).Patch(source.Name, types.MergePatchType, patch); err != nil {
+		return fmt.Errorf("patching backstage annotation: %w", err)
+	}
+
+	if source.Annotations == nil {
+		source.Annotations = map[string]string{}
+	}
+	source.Annotations[backstageIDAnnotation] = source.Name
+	return nil
+}
+
+func (r *Reconciler) createReceiveAdapter(ctx context.Context, src *v1alpha1.CouchDbSource, sinkURI string, hasEventPolicy bool) (*appsv1.Deployment, error) {
+	host, err := r.couchDbHost(src)
 	if err != nil {
 		return nil, err
 	}
-	logging.FromContext(ctx).Debug("event source", zap.Any("source", eventSource))
+	logging.FromContext(ctx).Debug("couchdb host", zap.String("host", host))
 
 	adapterArgs := resources.ReceiveAdapterArgs{
-		EventSource: eventSource,
-		Image:       r.receiveAdapterImage,
-		Source:      src,
-		Labels:      resources.Labels(src.Name),
-		SinkURI:     sinkURI,
+		EventSource:    host,
+		Image:          r.receiveAdapterImage,
+		Source:         src,
+		Labels:         resources.Labels(src.Name),
+		SinkURI:        sinkURI,
+		OIDCAudience:   sinkURI,
+		HasEventPolicy: hasEventPolicy,
 	}
 	expected := resources.MakeReceiveAdapter(&adapterArgs)
 
@@ -154,8 +244,14 @@ func (r *Reconciler) createReceiveAdapter(ctx context.Context, src *v1alpha1.Cou
 		return nil, fmt.Errorf("error getting receive adapter: %v", err)
 	} else if !metav1.IsControlledBy(ra, src) {
 		return nil, fmt.Errorf("deployment %q is not owned by CouchDbSource %q", ra.Name, src.Name)
-	} else if r.podSpecChanged(ra.Spec.Template.Spec, expected.Spec.Template.Spec) {
+	} else if r.podSpecChanged(ra.Spec.Template.Spec, expected.Spec.Template.Spec) ||
+		(expected.Spec.Replicas != nil && !equality.Semantic.DeepEqual(ra.Spec.Replicas, expected.Spec.Replicas)) {
 		ra.Spec.Template.Spec = expected.Spec.Template.Spec
+		// A nil expected Replicas means the PodAutoscaler owns the replica
+		// count, so the existing value is kept.
+		if expected.Spec.Replicas != nil {
+			ra.Spec.Replicas = expected.Spec.Replicas
+		}
 		if ra, err = r.KubeClientSet.AppsV1().Deployments(src.Namespace).Update(ra); err != nil {
 			return ra, err
 		}
@@ -167,6 +263,72 @@ func (r *Reconciler) createReceiveAdapter(ctx context.Context, src *v1alpha1.Cou
 	return ra, nil
 }
 
+// reconcileCheckpoint ensures the ConfigMap the receive adapter persists its
+// last-delivered _changes sequence to exists. When src.Spec.CheckpointRef is
+// unset, that ConfigMap is auto-created and owned by src so it is
+// garbage-collected via ownerReferences when src is deleted. When
+// CheckpointRef is explicitly set, it must already exist and is left as-is:
+// it's the user's ConfigMap, not auto-created and not claimed by src, since
+// the user may be pointing at one they manage independently. Either way, the
+// adapter itself reads and patches the ConfigMap's contents directly; the
+// reconciler only guarantees it exists.
+func (r *Reconciler) reconcileCheckpoint(ctx context.Context, src *v1alpha1.CouchDbSource) error {
+	expected := resources.MakeCheckpointConfigMap(src)
+	explicitRef := src.Spec.CheckpointRef != nil && src.Spec.CheckpointRef.Name != ""
+
+	existing, err := r.configMapLister.ConfigMaps(src.Namespace).Get(expected.Name)
+	if apierrors.IsNotFound(err) {
+		if explicitRef {
+			return fmt.Errorf("checkpointRef configmap %q does not exist", expected.Name)
+		}
+		_, err = r.KubeClientSet.CoreV1().ConfigMaps(src.Namespace).Create(expected)
+		return err
+	} else if err != nil {
+		return fmt.Errorf("getting checkpoint configmap: %w", err)
+	} else if explicitRef {
+		return nil
+	} else if !metav1.IsControlledBy(existing, src) {
+		return fmt.Errorf("configmap %q is not owned by CouchDbSource %q", existing.Name, src.Name)
+	}
+	return nil
+}
+
+// reconcilePodAutoscaler keeps the PodAutoscaler driving the receive
+// adapter's scale subresource in sync with src.Spec.Scale: creating or
+// updating it when Scale is set, and deleting any previously-created one
+// when it is unset so the Deployment falls back to a single replica.
+func (r *Reconciler) reconcilePodAutoscaler(ctx context.Context, src *v1alpha1.CouchDbSource) error {
+	name := kmeta.ChildName(src.Name, "-")
+
+	if src.Spec.Scale == nil {
+		if err := r.servingClientSet.ServingV1alpha1().PodAutoscalers(src.Namespace).Delete(name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("deleting pod autoscaler: %w", err)
+		}
+		return nil
+	}
+
+	expected := resources.MakePodAutoscaler(src)
+	if err := resources.ValidatePodAutoscalerAnnotations(expected); err != nil {
+		return fmt.Errorf("generated pod autoscaler is invalid: %w", err)
+	}
+
+	existing, err := r.podAutoscalerLister.PodAutoscalers(src.Namespace).Get(expected.Name)
+	if apierrors.IsNotFound(err) {
+		_, err = r.servingClientSet.ServingV1alpha1().PodAutoscalers(src.Namespace).Create(expected)
+		return err
+	} else if err != nil {
+		return fmt.Errorf("getting pod autoscaler: %w", err)
+	} else if !metav1.IsControlledBy(existing, src) {
+		return fmt.Errorf("pod autoscaler %q is not owned by CouchDbSource %q", existing.Name, src.Name)
+	} else if !equality.Semantic.DeepEqual(existing.Annotations, expected.Annotations) {
+		existing = existing.DeepCopy()
+		existing.Annotations = expected.Annotations
+		_, err = r.servingClientSet.ServingV1alpha1().PodAutoscalers(src.Namespace).Update(existing)
+		return err
+	}
+	return nil
+}
+
 func (r *Reconciler) reconcileEventTypes(ctx context.Context, src *v1alpha1.CouchDbSource) error {
 	current, err := r.getEventTypes(ctx, src)
 	if err != nil {
@@ -179,6 +341,11 @@ func (r *Reconciler) reconcileEventTypes(ctx context.Context, src *v1alpha1.Couc
 		return err
 	}
 
+	if err := r.stampConsumedBy(src, expected); err != nil {
+		logging.FromContext(ctx).Error("Unable to resolve EventType consumers", zap.Error(err))
+		return err
+	}
+
 	toCreate, toDelete := r.computeDiff(current, expected)
 
 	for _, eventType := range toDelete {
@@ -223,23 +390,95 @@ func (r *Reconciler) makeEventTypes(src *v1alpha1.CouchDbSource) ([]eventingv1al
 		return eventTypes, nil
 	}
 
-	source, err := r.makeEventSource(src)
+	host, err := r.couchDbHost(src)
 	if err != nil {
 		return nil, err
 	}
 
-	args := &resources.EventTypeArgs{
-		Src:    src,
-		Source: source,
-	}
-	for _, apiEventType := range couchDbEventTypes {
-		args.Type = apiEventType
-		eventType := resources.MakeEventType(args)
-		eventTypes = append(eventTypes, eventType)
+	args := &resources.EventTypeArgs{Src: src}
+	for _, db := range databases(src) {
+		args.Source = fmt.Sprintf("%s/%s", host, db)
+		for _, apiEventType := range couchDbEventTypes {
+			args.Type = apiEventType
+			eventType := resources.MakeEventType(args)
+			eventTypes = append(eventTypes, eventType)
+		}
 	}
 	return eventTypes, nil
 }
 
+// databases returns the set of CouchDB databases src watches, whether
+// configured through the legacy singular Database field or the new
+// Databases field.
+func databases(src *v1alpha1.CouchDbSource) []string {
+	if len(src.Spec.Databases) > 0 {
+		return src.Spec.Databases
+	}
+	return []string{src.Spec.Database}
+}
+
+// TriggerRef identifies a Trigger that subscribes to one of this source's
+// EventTypes, for the consumed-by annotation.
+type TriggerRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// stampConsumedBy resolves, for every expected EventType, which Triggers in
+// src.Namespace filter on its type, and stamps the result as a JSON-encoded
+// consumedByAnnotation so it stays in sync on every reconcile.
+func (r *Reconciler) stampConsumedBy(src *v1alpha1.CouchDbSource, expected []eventingv1alpha1.EventType) error {
+	triggers, err := r.triggerLister.Triggers(src.Namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("listing triggers: %w", err)
+	}
+
+	for i := range expected {
+		et := &expected[i]
+		consumers := []TriggerRef{}
+		for _, t := range triggers {
+			if triggerConsumes(t, et) {
+				consumers = append(consumers, TriggerRef{Name: t.Name, Namespace: t.Namespace})
+			}
+		}
+		// triggerLister.List order is not stable across calls, so sort before
+		// marshalling: otherwise the annotation's JSON would flap between
+		// reconciles with nothing actually changing, and computeDiff would
+		// read that as drift and delete/recreate the EventType forever.
+		sort.Slice(consumers, func(i, j int) bool {
+			if consumers[i].Namespace != consumers[j].Namespace {
+				return consumers[i].Namespace < consumers[j].Namespace
+			}
+			return consumers[i].Name < consumers[j].Name
+		})
+		raw, err := json.Marshal(consumers)
+		if err != nil {
+			return fmt.Errorf("marshalling consumers for EventType %q: %w", et.Spec.Type, err)
+		}
+		if et.Annotations == nil {
+			et.Annotations = map[string]string{}
+		}
+		et.Annotations[consumedByAnnotation] = string(raw)
+	}
+	return nil
+}
+
+// triggerConsumes reports whether t is bound to et's Broker and its filter
+// matches et's type. Within a set of filter attributes, a missing or empty
+// "type" attribute is a wildcard that subscribes to every type on the Broker,
+// including et's. A Trigger with no filter attributes at all is not counted
+// as a consumer.
+func triggerConsumes(t *eventingv1alpha1.Trigger, et *eventingv1alpha1.EventType) bool {
+	if t.Spec.Broker != et.Spec.Broker {
+		return false
+	}
+	if t.Spec.Filter == nil || t.Spec.Filter.Attributes == nil {
+		return false
+	}
+	filterType := (*t.Spec.Filter.Attributes)["type"]
+	return filterType == "" || filterType == et.Spec.Type
+}
+
 func (r *Reconciler) computeDiff(current []eventingv1alpha1.EventType, expected []eventingv1alpha1.EventType) ([]eventingv1alpha1.EventType, []eventingv1alpha1.EventType) {
 	toCreate := make([]eventingv1alpha1.EventType, 0)
 	toDelete := make([]eventingv1alpha1.EventType, 0)
@@ -251,7 +490,8 @@ func (r *Reconciler) computeDiff(current []eventingv1alpha1.EventType, expected
 		if c, ok := currentMap[keyFromEventType(&e)]; !ok {
 			toCreate = append(toCreate, e)
 		} else {
-			if !equality.Semantic.DeepEqual(e.Spec, c.Spec) {
+			if !equality.Semantic.DeepEqual(e.Spec, c.Spec) ||
+				!equality.Semantic.DeepEqual(e.Annotations, c.Annotations) {
 				toDelete = append(toDelete, c)
 				toCreate = append(toCreate, e)
 			}
@@ -280,10 +520,19 @@ func keyFromEventType(eventType *eventingv1alpha1.EventType) string {
 	return fmt.Sprintf("%s_%s_%s_%s", eventType.Spec.Type, eventType.Spec.Source, eventType.Spec.Schema, eventType.Spec.Broker)
 }
 
+// podSpecChanged reports whether newPodSpec differs from oldPodSpec in a way
+// that requires updating the running Deployment. DeepDerivative treats a
+// zero-valued field on newPodSpec as a wildcard, so it alone can't detect a
+// field going from set to unset (e.g. an EventPolicy's Volumes/VolumeMounts
+// or the autoscaler's metrics Ports disappearing); those fields, like Env,
+// are compared explicitly.
 func (r *Reconciler) podSpecChanged(oldPodSpec corev1.PodSpec, newPodSpec corev1.PodSpec) bool {
 	if !equality.Semantic.DeepDerivative(newPodSpec, oldPodSpec) {
 		return true
 	}
+	if !equality.Semantic.DeepEqual(newPodSpec.Volumes, oldPodSpec.Volumes) {
+		return true
+	}
 	if len(oldPodSpec.Containers) != len(newPodSpec.Containers) {
 		return true
 	}
@@ -291,6 +540,12 @@ func (r *Reconciler) podSpecChanged(oldPodSpec corev1.PodSpec, newPodSpec corev1
 		if !equality.Semantic.DeepEqual(newPodSpec.Containers[i].Env, oldPodSpec.Containers[i].Env) {
 			return true
 		}
+		if !equality.Semantic.DeepEqual(newPodSpec.Containers[i].VolumeMounts, oldPodSpec.Containers[i].VolumeMounts) {
+			return true
+		}
+		if !equality.Semantic.DeepEqual(newPodSpec.Containers[i].Ports, oldPodSpec.Containers[i].Ports) {
+			return true
+		}
 	}
 	return false
 }
@@ -313,8 +568,11 @@ func (r *Reconciler) getLabelSelector(src *v1alpha1.CouchDbSource) labels.Select
 	return labels.SelectorFromSet(resources.Labels(src.Name))
 }
 
-// MakeEventSource computes the Cloud Event source attribute for the given source
-func (r *Reconciler) makeEventSource(src *v1alpha1.CouchDbSource) (string, error) {
+// couchDbHost returns the hostname of the CouchDB instance src talks to. The
+// Cloud Event source attribute for a given change is this host plus the
+// database the change came from, computed per-event since a source can now
+// watch more than one database.
+func (r *Reconciler) couchDbHost(src *v1alpha1.CouchDbSource) (string, error) {
 	namespace := src.Spec.CouchDbCredentials.Namespace
 	if namespace == "" {
 		namespace = src.Namespace
@@ -336,5 +594,5 @@ func (r *Reconciler) makeEventSource(src *v1alpha1.CouchDbSource) (string, error
 		return "", err
 	}
 
-	return fmt.Sprintf("%s/%s", url.Hostname(), src.Spec.Database), nil
+	return url.Hostname(), nil
 }
@@ -0,0 +1,165 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	eventingv1alpha1 "knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+
+	"knative.dev/eventing-contrib/couchdb/source/pkg/apis/sources/v1alpha1"
+	cdbfake "knative.dev/eventing-contrib/couchdb/source/pkg/client/clientset/versioned/fake"
+)
+
+func TestTriggerConsumes(t *testing.T) {
+	et := &eventingv1alpha1.EventType{
+		Spec: eventingv1alpha1.EventTypeSpec{Type: "dev.knative.couchdb.update", Broker: "default"},
+	}
+
+	tests := []struct {
+		name string
+		t    *eventingv1alpha1.Trigger
+		want bool
+	}{{
+		name: "no filter",
+		t:    &eventingv1alpha1.Trigger{Spec: eventingv1alpha1.TriggerSpec{Broker: "default"}},
+		want: false,
+	}, {
+		name: "filter on all types",
+		t: &eventingv1alpha1.Trigger{Spec: eventingv1alpha1.TriggerSpec{
+			Broker: "default",
+			Filter: &eventingv1alpha1.TriggerFilter{
+				Attributes: &eventingv1alpha1.TriggerFilterAttributes{"type": ""},
+			},
+		}},
+		want: true,
+	}, {
+		name: "filter omits type attribute: wildcard",
+		t: &eventingv1alpha1.Trigger{Spec: eventingv1alpha1.TriggerSpec{
+			Broker: "default",
+			Filter: &eventingv1alpha1.TriggerFilter{
+				Attributes: &eventingv1alpha1.TriggerFilterAttributes{"source": "http://couchdb/orders"},
+			},
+		}},
+		want: true,
+	}, {
+		name: "filter matches type",
+		t: &eventingv1alpha1.Trigger{Spec: eventingv1alpha1.TriggerSpec{
+			Broker: "default",
+			Filter: &eventingv1alpha1.TriggerFilter{
+				Attributes: &eventingv1alpha1.TriggerFilterAttributes{"type": "dev.knative.couchdb.update"},
+			},
+		}},
+		want: true,
+	}, {
+		name: "filter does not match type",
+		t: &eventingv1alpha1.Trigger{Spec: eventingv1alpha1.TriggerSpec{
+			Broker: "default",
+			Filter: &eventingv1alpha1.TriggerFilter{
+				Attributes: &eventingv1alpha1.TriggerFilterAttributes{"type": "dev.knative.couchdb.delete"},
+			},
+		}},
+		want: false,
+	}, {
+		name: "matching type but different broker",
+		t: &eventingv1alpha1.Trigger{Spec: eventingv1alpha1.TriggerSpec{
+			Broker: "other",
+			Filter: &eventingv1alpha1.TriggerFilter{
+				Attributes: &eventingv1alpha1.TriggerFilterAttributes{"type": "dev.knative.couchdb.update"},
+			},
+		}},
+		want: false,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := triggerConsumes(test.t, et); got != test.want {
+				t.Errorf("triggerConsumes() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestComputeDiffAnnotationDrift(t *testing.T) {
+	r := &Reconciler{}
+	base := eventingv1alpha1.EventType{
+		Spec: eventingv1alpha1.EventTypeSpec{Type: "dev.knative.couchdb.update", Source: "http://couchdb/orders"},
+	}
+
+	current := base
+	current.Annotations = map[string]string{consumedByAnnotation: `[{"name":"a","namespace":"ns"}]`}
+
+	t.Run("same annotations: no diff", func(t *testing.T) {
+		expected := base
+		expected.Annotations = map[string]string{consumedByAnnotation: `[{"name":"a","namespace":"ns"}]`}
+
+		toCreate, toDelete := r.computeDiff([]eventingv1alpha1.EventType{current}, []eventingv1alpha1.EventType{expected})
+		if len(toCreate) != 0 || len(toDelete) != 0 {
+			t.Errorf("computeDiff() = toCreate %v, toDelete %v, want both empty", toCreate, toDelete)
+		}
+	})
+
+	t.Run("different annotations: recreate", func(t *testing.T) {
+		expected := base
+		expected.Annotations = map[string]string{consumedByAnnotation: `[{"name":"b","namespace":"ns"}]`}
+
+		toCreate, toDelete := r.computeDiff([]eventingv1alpha1.EventType{current}, []eventingv1alpha1.EventType{expected})
+		if len(toCreate) != 1 || len(toDelete) != 1 {
+			t.Errorf("computeDiff() = toCreate %v, toDelete %v, want exactly one of each", toCreate, toDelete)
+		}
+	})
+}
+
+func TestReconcileBackstageID(t *testing.T) {
+	src := &v1alpha1.CouchDbSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-source", Namespace: "default"},
+	}
+	r := &Reconciler{couchdbClientSet: cdbfake.NewSimpleClientset(src)}
+
+	if err := r.reconcileBackstageID(src); err != nil {
+		t.Fatalf("reconcileBackstageID() error = %v", err)
+	}
+	if got, want := src.Annotations[backstageIDAnnotation], "my-source"; got != want {
+		t.Errorf("in-memory annotation = %q, want %q", got, want)
+	}
+
+	persisted, err := r.couchdbClientSet.SourcesV1alpha1().CouchDbSources(src.Namespace).Get(src.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got, want := persisted.Annotations[backstageIDAnnotation], "my-source"; got != want {
+		t.Errorf("persisted annotation = %q, want %q", got, want)
+	}
+}
+
+func TestReconcileBackstageIDAlreadyStamped(t *testing.T) {
+	src := &v1alpha1.CouchDbSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "already-stamped",
+			Annotations: map[string]string{backstageIDAnnotation: "already-stamped"},
+		},
+	}
+	// No couchdbClientSet: a Patch call here would panic, proving a source
+	// that's already stamped skips the API call entirely.
+	r := &Reconciler{}
+
+	if err := r.reconcileBackstageID(src); err != nil {
+		t.Errorf("reconcileBackstageID() error = %v", err)
+	}
+}
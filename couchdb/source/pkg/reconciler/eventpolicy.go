@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	eventingv1alpha1 "knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+
+	"knative.dev/eventing-contrib/couchdb/source/pkg/apis/sources/v1alpha1"
+)
+
+// reconcileEventPolicies lists the EventPolicies in src's namespace that
+// select src and reports whether any ready EventPolicy does, which gates
+// attaching an OIDC token to the receive adapter's outgoing requests for the
+// sink to enforce.
+//
+// It returns false (with EventPoliciesReady left True) when no EventPolicy
+// selects src, matching how EventPolicy attachment is optional elsewhere in
+// eventing.
+func (r *Reconciler) reconcileEventPolicies(ctx context.Context, src *v1alpha1.CouchDbSource) (bool, error) {
+	all, err := r.eventPolicyLister.EventPolicies(src.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, fmt.Errorf("listing event policies: %w", err)
+	}
+
+	selecting := make([]*eventingv1alpha1.EventPolicy, 0, len(all))
+	for _, ep := range all {
+		if eventPolicySelects(ep, src) {
+			selecting = append(selecting, ep)
+		}
+	}
+
+	if len(selecting) == 0 {
+		src.Status.MarkEventPoliciesTrue()
+		return false, nil
+	}
+
+	for _, ep := range selecting {
+		if !eventPolicyIsReady(ep) {
+			src.Status.MarkEventPoliciesFalse(eventPolicyNotReady,
+				"EventPolicy %q has not converged", ep.Name)
+			r.Recorder.Eventf(src, corev1.EventTypeWarning, eventPolicyNotReady,
+				"EventPolicy %q selects this CouchDbSource but is not ready", ep.Name)
+			return false, fmt.Errorf("event policy %q is not ready", ep.Name)
+		}
+	}
+
+	src.Status.MarkEventPoliciesTrue()
+	return true, nil
+}
+
+// eventPolicySelects reports whether ep.Spec.To selects src, either by a
+// reference whose Kind is CouchDbSource and whose Name is src's, or by label
+// selector. A reference's APIVersion is not compared.
+func eventPolicySelects(ep *eventingv1alpha1.EventPolicy, src *v1alpha1.CouchDbSource) bool {
+	if len(ep.Spec.To) == 0 {
+		// An EventPolicy with no `to` applies to every resource in the namespace.
+		return true
+	}
+	for _, to := range ep.Spec.To {
+		if to.Ref != nil && to.Ref.Kind == "CouchDbSource" && to.Ref.Name == src.Name {
+			return true
+		}
+		if to.Selector != nil {
+			sel, err := metav1.LabelSelectorAsSelector(to.Selector)
+			if err == nil && sel.Matches(labels.Set(src.Labels)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// eventPolicyIsReady reports whether ep has converged.
+func eventPolicyIsReady(ep *eventingv1alpha1.EventPolicy) bool {
+	return ep.Status.IsReady()
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+
+	"knative.dev/eventing-contrib/couchdb/source/pkg/apis/sources/v1alpha1"
+)
+
+func TestMakePodAutoscalerPassesOwnValidation(t *testing.T) {
+	max := int32(5)
+	src := &v1alpha1.CouchDbSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-source", Namespace: "default"},
+		Spec: v1alpha1.CouchDbSourceSpec{
+			Scale: &v1alpha1.ScaleSpec{MaxReplicas: max, TargetLag: 100},
+		},
+	}
+
+	pa := MakePodAutoscaler(src)
+	if err := ValidatePodAutoscalerAnnotations(pa); err != nil {
+		t.Errorf("ValidatePodAutoscalerAnnotations() = %v, want nil", err)
+	}
+}
+
+func TestValidatePodAutoscalerAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     bool
+	}{{
+		name:        "valid",
+		annotations: map[string]string{classAnnotation: couchDBAutoscalerClass, metricAnnotation: couchDBLagMetric},
+		wantErr:     false,
+	}, {
+		name:        "wrong class",
+		annotations: map[string]string{classAnnotation: "KPA", metricAnnotation: couchDBLagMetric},
+		wantErr:     true,
+	}, {
+		name:        "wrong metric",
+		annotations: map[string]string{classAnnotation: couchDBAutoscalerClass, metricAnnotation: "concurrency"},
+		wantErr:     true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pa := &servingv1alpha1.PodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "pa", Annotations: test.annotations},
+			}
+			err := ValidatePodAutoscalerAnnotations(pa)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("ValidatePodAutoscalerAnnotations() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
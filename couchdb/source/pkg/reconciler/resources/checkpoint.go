@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/kmeta"
+
+	"knative.dev/eventing-contrib/couchdb/source/pkg/apis/sources/v1alpha1"
+)
+
+// CheckpointConfigMapName returns the name of the ConfigMap the receive
+// adapter persists its last-delivered _changes sequence to, honoring
+// src.Spec.CheckpointRef when set and otherwise defaulting to
+// "<name>-checkpoint".
+func CheckpointConfigMapName(src *v1alpha1.CouchDbSource) string {
+	if src.Spec.CheckpointRef != nil && src.Spec.CheckpointRef.Name != "" {
+		return src.Spec.CheckpointRef.Name
+	}
+	return kmeta.ChildName(src.Name, "-checkpoint")
+}
+
+// MakeCheckpointConfigMap generates (but does not submit to the API server)
+// the ConfigMap the receive adapter reads its initial _changes sequence from
+// on startup, and patches with the last sequence it has successfully
+// delivered, keyed by database name.
+func MakeCheckpointConfigMap(src *v1alpha1.CouchDbSource) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            CheckpointConfigMapName(src),
+			Namespace:       src.Namespace,
+			Labels:          Labels(src.Name),
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(src)},
+		},
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resources contains helpers for reconciling the objects owned by a
+// CouchDbSource.
+package resources
+
+import (
+	"encoding/json"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/kmeta"
+
+	"knative.dev/eventing-contrib/couchdb/source/pkg/apis/sources/v1alpha1"
+)
+
+const (
+	envSink                = "SINK_URI"
+	envCouchDbCredentials  = "COUCHDB_CREDENTIALS"
+	envEventSource         = "EVENT_SOURCE"
+	envDatabase            = "DATABASE"
+	envDatabases           = "DATABASES"
+	envSelector            = "SELECTOR"
+	envCEOverrides         = "K_CE_OVERRIDES"
+	envOIDCAudience        = "OIDC_AUDIENCE"
+	envMetricsAddr         = "METRICS_ADDR"
+	envCheckpointConfigMap = "CHECKPOINT_CONFIGMAP"
+	envCheckpointNamespace = "CHECKPOINT_NAMESPACE"
+
+	// metricsAddr is where the receive adapter serves couchdb.knative.dev/lag
+	// for the PodAutoscaler's metrics collector to scrape, once src.Spec.Scale
+	// is set.
+	metricsAddr = ":9090"
+	metricsPort = 9090
+
+	oidcTokenVolumeName = "couchdb-source-token"
+	oidcTokenMountPath  = "/var/run/secrets/tokens"
+	oidcTokenExpiration = int64(3600)
+
+	checkpointVolumeName = "checkpoint"
+	checkpointMountPath  = "/etc/couchdb-source/checkpoint"
+)
+
+// ReceiveAdapterArgs are the arguments needed to create a receive adapter
+// Deployment for a CouchDbSource. EventSource, Image, Source, Labels and
+// SinkURI are required. HasEventPolicy is only set once a ready EventPolicy
+// selects the source, and OIDCAudience is only used when HasEventPolicy is set.
+type ReceiveAdapterArgs struct {
+	EventSource string
+	Image       string
+	Source      *v1alpha1.CouchDbSource
+	Labels      map[string]string
+	SinkURI     string
+
+	// OIDCAudience is the audience the receive adapter requests when minting
+	// an OIDC token to attach to outgoing requests.
+	OIDCAudience string
+	// HasEventPolicy reports whether a ready EventPolicy selects the source,
+	// gating attaching an OIDC token to outgoing requests for the sink to
+	// enforce.
+	HasEventPolicy bool
+}
+
+// Labels returns the labels used on all objects owned by a CouchDbSource
+// named name.
+func Labels(name string) map[string]string {
+	return map[string]string{
+		"knative-eventing-source":      "couchdb-source",
+		"knative-eventing-source-name": name,
+	}
+}
+
+// MakeReceiveAdapter generates (but does not submit to the API server) the
+// Deployment for the CouchDB receive adapter. When src.Spec.Scale is unset,
+// Replicas is pinned to 1 for backward compatibility; otherwise it is left
+// to the PodAutoscaler driving the Deployment's scale subresource.
+func MakeReceiveAdapter(args *ReceiveAdapterArgs) *appsv1.Deployment {
+	var replicas *int32
+	if args.Source.Spec.Scale == nil {
+		r := int32(1)
+		replicas = &r
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            kmeta.ChildName(args.Source.Name, "-"),
+			Namespace:       args.Source.Namespace,
+			Labels:          args.Labels,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(args.Source)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: args.Labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: args.Labels,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: "couchdb-source-adapter",
+					Containers: []corev1.Container{
+						{
+							Name:         "receive-adapter",
+							Image:        args.Image,
+							Env:          makeEnv(args),
+							Ports:        makeContainerPorts(args),
+							VolumeMounts: makeVolumeMounts(args),
+						},
+					},
+					Volumes: makeVolumes(args),
+				},
+			},
+		},
+	}
+}
+
+func makeEnv(args *ReceiveAdapterArgs) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{
+			Name:  envSink,
+			Value: args.SinkURI,
+		},
+		{
+			Name: envCouchDbCredentials,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &args.Source.Spec.CouchDbCredentials,
+			},
+		},
+		{
+			Name:  envEventSource,
+			Value: args.EventSource,
+		},
+		{
+			Name:  envCheckpointConfigMap,
+			Value: CheckpointConfigMapName(args.Source),
+		},
+		{
+			Name:  envCheckpointNamespace,
+			Value: args.Source.Namespace,
+		},
+	}
+
+	if dbs := args.Source.Spec.Databases; len(dbs) > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  envDatabases,
+			Value: strings.Join(dbs, ","),
+		})
+	} else {
+		env = append(env, corev1.EnvVar{
+			Name:  envDatabase,
+			Value: args.Source.Spec.Database,
+		})
+	}
+
+	if sel := args.Source.Spec.Selector; sel != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  envSelector,
+			Value: string(sel.Raw),
+		})
+	}
+
+	if overrides := ceOverridesJSON(args.Source); overrides != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  envCEOverrides,
+			Value: overrides,
+		})
+	}
+
+	if args.HasEventPolicy {
+		env = append(env, corev1.EnvVar{
+			Name:  envOIDCAudience,
+			Value: args.OIDCAudience,
+		})
+	}
+
+	if args.Source.Spec.Scale != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  envMetricsAddr,
+			Value: metricsAddr,
+		})
+	}
+
+	return env
+}
+
+// makeContainerPorts exposes the metrics port once the adapter is configured
+// to serve couchdb.knative.dev/lag, i.e. once src.Spec.Scale is set.
+func makeContainerPorts(args *ReceiveAdapterArgs) []corev1.ContainerPort {
+	if args.Source.Spec.Scale == nil {
+		return nil
+	}
+	return []corev1.ContainerPort{{
+		Name:          "metrics",
+		ContainerPort: metricsPort,
+	}}
+}
+
+// makeVolumes mounts the checkpoint ConfigMap the adapter resumes from on
+// startup, plus a projected OIDC token for the configured audience once an
+// EventPolicy selects the source.
+func makeVolumes(args *ReceiveAdapterArgs) []corev1.Volume {
+	checkpointOptional := true
+	volumes := []corev1.Volume{
+		{
+			Name: checkpointVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: CheckpointConfigMapName(args.Source),
+					},
+					Optional: &checkpointOptional,
+				},
+			},
+		},
+	}
+
+	if !args.HasEventPolicy {
+		return volumes
+	}
+
+	expiration := oidcTokenExpiration
+	return append(volumes,
+		corev1.Volume{
+			Name: oidcTokenVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Path:              "couchdb-source-token",
+							Audience:          args.OIDCAudience,
+							ExpirationSeconds: &expiration,
+						},
+					}},
+				},
+			},
+		},
+	)
+}
+
+func makeVolumeMounts(args *ReceiveAdapterArgs) []corev1.VolumeMount {
+	mounts := []corev1.VolumeMount{
+		{
+			Name:      checkpointVolumeName,
+			MountPath: checkpointMountPath,
+			ReadOnly:  true,
+		},
+	}
+
+	if !args.HasEventPolicy {
+		return mounts
+	}
+
+	return append(mounts,
+		corev1.VolumeMount{
+			Name:      oidcTokenVolumeName,
+			MountPath: oidcTokenMountPath,
+			ReadOnly:  true,
+		},
+	)
+}
+
+// ceOverridesJSON marshals the source's CloudEventOverrides.Extensions into
+// the bare {"k":"v"} JSON the receive adapter decodes from K_CE_OVERRIDES
+// (cmd/receive_adapter/main.go's parseCEOverrides). This is this adapter's
+// own convention, not the full CloudEventOverrides envelope other Knative
+// sources put on the wire; keep both sides in lockstep if this ever moves
+// onto the shared adapter framework.
+func ceOverridesJSON(src *v1alpha1.CouchDbSource) string {
+	if src.Spec.CloudEventOverrides == nil || len(src.Spec.CloudEventOverrides.Extensions) == 0 {
+		return ""
+	}
+	raw, err := json.Marshal(src.Spec.CloudEventOverrides.Extensions)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
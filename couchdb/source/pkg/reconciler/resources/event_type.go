@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/kmeta"
+
+	eventingv1alpha1 "knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+
+	"knative.dev/eventing-contrib/couchdb/source/pkg/apis/sources/v1alpha1"
+)
+
+// EventTypeArgs are the arguments needed to create an EventType for a
+// CouchDbSource.
+type EventTypeArgs struct {
+	Src    *v1alpha1.CouchDbSource
+	Source string
+	Type   string
+}
+
+// MakeEventType generates (but does not submit to the API server) the
+// EventType for the given CouchDbSource, api event type and CE source.
+func MakeEventType(args *EventTypeArgs) eventingv1alpha1.EventType {
+	return eventingv1alpha1.EventType{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName:    kmeta.ChildName(args.Src.Name+"-", ""),
+			Namespace:       args.Src.Namespace,
+			Labels:          Labels(args.Src.Name),
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(args.Src)},
+		},
+		Spec: eventingv1alpha1.EventTypeSpec{
+			Type:   args.Type,
+			Source: args.Source,
+			Broker: args.Src.Spec.Sink.GetRef().Name,
+		},
+	}
+}